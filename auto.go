@@ -0,0 +1,244 @@
+package vpack
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// autoPlanCache memoizes the field plan built for each type passed to Auto,
+// so the reflection walk only happens once per T.
+var autoPlanCache sync.Map // map[reflect.Type]*autoPlan
+
+// autoPlan is the cached result of reflecting over a struct type: which
+// fields to (de)serialize, at what offset, and with which encoder.
+type autoPlan struct {
+	fields []autoField
+}
+
+type autoField struct {
+	name   string
+	offset uintptr
+	encode func(ptr unsafe.Pointer, buf PackTarget)
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Auto opportunistically (de)serializes obj by reflecting over its struct
+// fields once per type T and caching a plan driven by `vpack:"..."` tags, so
+// every later call skips reflection and runs the cached plan directly
+// against obj's memory via unsafe.Pointer arithmetic.
+//
+// Supported tags: `vpack:"fint64"` selects the fixed-width big endian
+// encoding for an int/int64/uint64 field instead of the varint default;
+// `vpack:"fint32"`/`vpack:"fint16"` do the same for uint32/uint16 fields;
+// `vpack:"stringz"` selects null-terminated encoding for a string field
+// instead of the length-prefixed default; `vpack:"unixmilli"` encodes a
+// time.Time field as a millisecond unix timestamp instead of via
+// encoding.BinaryMarshaler; `vpack:"-"` skips the field entirely.
+//
+// Auto trades away some of the control the explicit PackFn style gives you
+// for much less boilerplate. Prefer the explicit style for hot code, or for
+// fields that need custom handling Auto doesn't support.
+func Auto[T any](obj *T, buf PackTarget) {
+	typ := reflect.TypeOf(*obj)
+	plan := loadAutoPlan(typ)
+	base := unsafe.Pointer(obj)
+	for _, f := range plan.fields {
+		buf.PushField(f.name)
+		f.encode(unsafe.Add(base, f.offset), buf)
+		buf.PopField()
+	}
+}
+
+func loadAutoPlan(typ reflect.Type) *autoPlan {
+	if cached, ok := autoPlanCache.Load(typ); ok {
+		return cached.(*autoPlan)
+	}
+	plan := buildAutoPlan(typ)
+	actual, _ := autoPlanCache.LoadOrStore(typ, plan)
+	return actual.(*autoPlan)
+}
+
+func buildAutoPlan(typ reflect.Type) *autoPlan {
+	var plan autoPlan
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("vpack")
+		if tag == "-" {
+			continue
+		}
+
+		encode := autoEncoderFor(field.Type, tag)
+		if encode == nil {
+			panic(fmt.Sprintf("vpack: Auto doesn't know how to pack %s.%s (%s); "+
+				"give it an explicit vpack tag or a hand-written PackFn instead",
+				typ, field.Name, field.Type))
+		}
+
+		plan.fields = append(plan.fields, autoField{
+			name:   field.Name,
+			offset: field.Offset,
+			encode: encode,
+		})
+	}
+	return &plan
+}
+
+// autoEncoderFor returns the (ptr, PackTarget) closure that (de)serializes a
+// field of the given type and tag, or nil if Auto doesn't support it.
+func autoEncoderFor(typ reflect.Type, tag string) func(unsafe.Pointer, PackTarget) {
+	switch {
+	case typ == timeType:
+		if tag == "unixmilli" {
+			return func(p unsafe.Pointer, buf PackTarget) { UnixTimeMilli((*time.Time)(p), buf) }
+		}
+		return func(p unsafe.Pointer, buf PackTarget) { Time((*time.Time)(p), buf) }
+
+	case typ.Kind() == reflect.Bool:
+		return func(p unsafe.Pointer, buf PackTarget) { Bool((*bool)(p), buf) }
+
+	case typ.Kind() == reflect.Int:
+		return func(p unsafe.Pointer, buf PackTarget) { Int((*int)(p), buf) }
+
+	case typ.Kind() == reflect.Int64:
+		if tag == "fint64" {
+			return func(p unsafe.Pointer, buf PackTarget) { FInt64((*int64)(p), buf) }
+		}
+		return func(p unsafe.Pointer, buf PackTarget) { VInt64((*int64)(p), buf) }
+
+	case typ.Kind() == reflect.Uint64:
+		if tag == "fint64" {
+			return func(p unsafe.Pointer, buf PackTarget) { FUInt64((*uint64)(p), buf) }
+		}
+		return func(p unsafe.Pointer, buf PackTarget) { VUInt64((*uint64)(p), buf) }
+
+	case typ.Kind() == reflect.Int32:
+		return func(p unsafe.Pointer, buf PackTarget) {
+			ptr := (*int32)(p)
+			n64 := int64(*ptr)
+			VInt64(&n64, buf)
+			*ptr = int32(n64)
+		}
+
+	case typ.Kind() == reflect.Uint32:
+		if tag == "fint32" {
+			return func(p unsafe.Pointer, buf PackTarget) { FUInt32((*uint32)(p), buf) }
+		}
+		return func(p unsafe.Pointer, buf PackTarget) {
+			ptr := (*uint32)(p)
+			n64 := uint64(*ptr)
+			VUInt64(&n64, buf)
+			*ptr = uint32(n64)
+		}
+
+	case typ.Kind() == reflect.Int16:
+		return func(p unsafe.Pointer, buf PackTarget) {
+			ptr := (*int16)(p)
+			n64 := int64(*ptr)
+			VInt64(&n64, buf)
+			*ptr = int16(n64)
+		}
+
+	case typ.Kind() == reflect.Uint16:
+		if tag == "fint16" {
+			return func(p unsafe.Pointer, buf PackTarget) { FUInt16((*uint16)(p), buf) }
+		}
+		return func(p unsafe.Pointer, buf PackTarget) {
+			ptr := (*uint16)(p)
+			n64 := uint64(*ptr)
+			VUInt64(&n64, buf)
+			*ptr = uint16(n64)
+		}
+
+	case typ.Kind() == reflect.Int8:
+		return func(p unsafe.Pointer, buf PackTarget) {
+			ptr := (*int8)(p)
+			b := byte(*ptr)
+			Byte(&b, buf)
+			*ptr = int8(b)
+		}
+
+	case typ.Kind() == reflect.Uint8: // also covers the byte alias
+		return func(p unsafe.Pointer, buf PackTarget) { Byte((*byte)(p), buf) }
+
+	case typ.Kind() == reflect.Uint:
+		return func(p unsafe.Pointer, buf PackTarget) { UInt((*uint)(p), buf) }
+
+	case typ.Kind() == reflect.Float64:
+		return func(p unsafe.Pointer, buf PackTarget) { Float64((*float64)(p), buf) }
+
+	case typ.Kind() == reflect.String:
+		if tag == "stringz" {
+			return func(p unsafe.Pointer, buf PackTarget) { StringZ((*string)(p), buf) }
+		}
+		return func(p unsafe.Pointer, buf PackTarget) { String((*string)(p), buf) }
+	}
+	return nil
+}
+
+// versionRegistryMu guards writes to versionRegistry. RegisterVersion is
+// meant to be called during init, but the lock keeps concurrent
+// registration (e.g. from multiple packages' init funcs) safe.
+var versionRegistryMu sync.Mutex
+var versionRegistry sync.Map // map[reflect.Type]map[int]any
+
+// RegisterVersion registers planFn as version v's (de)serializer for T.
+// Register every version T has ever had, oldest first, typically from an
+// init func; AutoVersioned picks among them based on the version prefix it
+// reads or writes.
+func RegisterVersion[T any](v int, planFn PackFn[T]) {
+	var zero T
+	typ := reflect.TypeOf(zero)
+
+	versionRegistryMu.Lock()
+	defer versionRegistryMu.Unlock()
+
+	raw, _ := versionRegistry.LoadOrStore(typ, map[int]any{})
+	versions := raw.(map[int]any)
+	versions[v] = planFn
+}
+
+// AutoVersioned reads/writes a version number (capped at the highest
+// version registered for T via RegisterVersion), then dispatches to that
+// version's PackFn. It panics if no version has been registered for T,
+// since that's a programming error rather than a data error.
+//
+// versionRegistry's per-type map is shared with RegisterVersion, so every
+// access to it — including this one — must hold versionRegistryMu; a plain
+// unsynchronized read here would race with concurrent registration.
+func AutoVersioned[T any](obj *T, buf PackTarget) {
+	var zero T
+	typ := reflect.TypeOf(zero)
+
+	versionRegistryMu.Lock()
+	raw, ok := versionRegistry.Load(typ)
+	if !ok {
+		versionRegistryMu.Unlock()
+		panic(fmt.Sprintf("vpack: no versions registered for %s; call RegisterVersion first", typ))
+	}
+	versions := raw.(map[int]any)
+
+	maxVersion := 0
+	for v := range versions {
+		if v > maxVersion {
+			maxVersion = v
+		}
+	}
+	versionRegistryMu.Unlock()
+
+	v := Version(maxVersion, buf)
+
+	versionRegistryMu.Lock()
+	planFnAny := versions[v]
+	versionRegistryMu.Unlock()
+
+	planFn, ok := planFnAny.(PackFn[T])
+	if !ok {
+		buf.Fail(fmt.Errorf("vpack: no PackFn registered for %s version %d", typ, v))
+		return
+	}
+	planFn(obj, buf)
+}
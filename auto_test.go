@@ -0,0 +1,149 @@
+package vpack
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestAutoRoundTrip(t *testing.T) {
+	type Profile struct {
+		Name    string `vpack:"stringz"`
+		Age     int
+		ID      uint64 `vpack:"fint64"`
+		Hidden  string `vpack:"-"`
+		Score   float64
+		Enabled bool
+	}
+
+	original := Profile{
+		Name:    "ada",
+		Age:     36,
+		ID:      7,
+		Hidden:  "not serialized",
+		Score:   99.5,
+		Enabled: true,
+	}
+
+	data := ToBytes(&original, func(p *Profile, buf PackTarget) { Auto(p, buf) })
+	if data == nil {
+		t.Fatal("packing failed")
+	}
+
+	var result Profile
+	ok := FromBytesInto(data, &result, func(p *Profile, buf PackTarget) { Auto(p, buf) })
+	if !ok {
+		t.Fatal("unpacking failed")
+	}
+
+	result.Hidden = original.Hidden // skipped field isn't round-tripped
+	if result != original {
+		t.Fatalf("objects don't match: %+v != %+v", result, original)
+	}
+}
+
+func TestAutoRoundTripPrimitiveWidths(t *testing.T) {
+	type Widths struct {
+		I32 int32
+		U32 uint32 `vpack:"fint32"`
+		I16 int16
+		U16 uint16 `vpack:"fint16"`
+		I8  int8
+		U8  uint8
+		B   byte
+		U   uint
+	}
+
+	original := Widths{
+		I32: -1234567,
+		U32: 1234567,
+		I16: -1234,
+		U16: 1234,
+		I8:  -12,
+		U8:  12,
+		B:   0xAB,
+		U:   42,
+	}
+
+	data := ToBytes(&original, func(w *Widths, buf PackTarget) { Auto(w, buf) })
+	if data == nil {
+		t.Fatal("packing failed")
+	}
+
+	var result Widths
+	ok := FromBytesInto(data, &result, func(w *Widths, buf PackTarget) { Auto(w, buf) })
+	if !ok {
+		t.Fatal("unpacking failed")
+	}
+	if result != original {
+		t.Fatalf("objects don't match: %+v != %+v", result, original)
+	}
+}
+
+// TestAutoVersionedConcurrent guards against a data race between
+// RegisterVersion and AutoVersioned reading/writing the shared per-type
+// version map; run with -race to catch a regression.
+func TestAutoVersionedConcurrent(t *testing.T) {
+	type Gadget struct {
+		N int
+	}
+
+	RegisterVersion(1, func(g *Gadget, buf PackTarget) { Int(&g.N, buf) })
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			obj := Gadget{N: n}
+			data := ToBytes(&obj, AutoVersioned[Gadget])
+			var result Gadget
+			FromBytesInto(data, &result, AutoVersioned[Gadget])
+		}(i)
+	}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(v int) {
+			defer wg.Done()
+			RegisterVersion(v, func(g *Gadget, buf PackTarget) { Int(&g.N, buf) })
+		}(100 + i)
+	}
+	wg.Wait()
+}
+
+func TestAutoVersioned(t *testing.T) {
+	type Widget struct {
+		Units int
+		Price int
+	}
+
+	RegisterVersion(1, func(w *Widget, buf PackTarget) {
+		var units int
+		if buf.Writing() {
+			units = w.Units
+		}
+		Int(&units, buf)
+		if !buf.Writing() {
+			w.Units = units
+			w.Price = units * 10
+		}
+	})
+	RegisterVersion(2, func(w *Widget, buf PackTarget) {
+		Int(&w.Units, buf)
+		Int(&w.Price, buf)
+	})
+
+	original := Widget{Units: 3, Price: 42}
+	data := ToBytes(&original, AutoVersioned[Widget])
+	if data == nil {
+		t.Fatal("packing failed")
+	}
+
+	var result Widget
+	ok := FromBytesInto(data, &result, AutoVersioned[Widget])
+	if !ok {
+		t.Fatal("unpacking failed")
+	}
+	if result != original {
+		t.Fatalf("objects don't match: %+v != %+v", result, original)
+	}
+}
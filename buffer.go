@@ -1,6 +1,10 @@
-package store
+package vpack
 
-import "io"
+import (
+	"fmt"
+	"io"
+	"strings"
+)
 
 type Mode int
 
@@ -14,8 +18,53 @@ const (
 type Buffer struct {
 	Data  []byte
 	Pos   int  // reading position; not used for writing
-	Error bool // TODO: something better than this to report errors with more info?
+	Error bool // true once a failure has been recorded; see Err for details
 	Mode  Mode
+
+	err  *PackError
+	path []string
+}
+
+// Err returns the structured error recorded for this buffer, or nil if
+// nothing has failed yet. Error is kept around (and kept in sync with Err)
+// for source compatibility with code that only checks the bool.
+func (b *Buffer) Err() *PackError {
+	return b.err
+}
+
+// Fail records cause as the reason this buffer's (de)serialization failed,
+// tagged with the current field path (see PushField) and the buffer's
+// current position. Only the first failure is recorded, since it's usually
+// the most specific one; later ones are typically just fallout from the
+// first.
+func (b *Buffer) Fail(cause error) {
+	b.Error = true
+	if b.err != nil {
+		return
+	}
+	op := "read"
+	if b.Mode == Serialize {
+		op = "write"
+	}
+	b.err = &PackError{
+		Op:     op,
+		Offset: b.Pos,
+		Path:   strings.Join(b.path, "."),
+		Cause:  cause,
+	}
+}
+
+// PushField records that buf is about to (de)serialize the named field, so
+// that a failure underneath it is reported with a breadcrumb such as
+// `O1[1].S1`. Every PushField must be paired with a PopField, typically via
+// defer. See Field for a wrapper that does this automatically.
+func (b *Buffer) PushField(name string) {
+	b.path = append(b.path, name)
+}
+
+// PopField removes the most recently pushed field name.
+func (b *Buffer) PopField() {
+	b.path = b.path[:len(b.path)-1]
 }
 
 // NewReader prepares a Buffer for deserializing data from
@@ -41,6 +90,14 @@ func (b *Buffer) ReadingDone() bool {
 	return b.Pos >= len(b.Data)
 }
 
+// Writing reports whether this buffer is in serialization (as opposed to
+// deserialization) mode. It's part of the PackTarget interface so a PackFn
+// doesn't need to know whether it's writing to an in-memory Buffer or a
+// streaming StreamBuffer.
+func (b *Buffer) Writing() bool {
+	return b.Mode == Serialize
+}
+
 // Ensure there's at least n bytes in the buffer starting from current position
 func (b *Buffer) EnsureSpace(n int) {
 	var desiredSize = len(b.Data) + n
@@ -77,7 +134,7 @@ func (b *Buffer) ReadBytes(n int) []byte {
 		result := make([]byte, n)
 		copy(result, remaining)
 		b.Pos = length
-		b.Error = true
+		b.Fail(fmt.Errorf("%w (need %d, have %d)", ErrShortRead, n, len(remaining)))
 		return result
 	}
 	start := b.Pos // superfluous var for readability?
@@ -7,17 +7,29 @@ import "errors"
 var GenericError = errors.New("Deserialization error")
 
 // PackFn is a generic serialization function that can be used either to
-// serialize or deserialize data, depending on the buffer's mode.
-type PackFn[T any] func(data *T, buffer *Buffer)
+// serialize or deserialize data, depending on the target's mode. buffer is a
+// PackTarget rather than a concrete *Buffer so the same PackFn works
+// unmodified against an in-memory Buffer or a streaming StreamBuffer backed
+// by an io.Reader/io.Writer.
+type PackFn[T any] func(data *T, buffer PackTarget)
 
+// ToBytes packs obj using fn and returns a freshly allocated, caller-owned
+// byte slice. It uses a pooled scratch Buffer internally (see AcquireWriter)
+// so repeated calls don't each pay for a new backing array, but the
+// returned slice itself is always a copy since the scratch buffer is
+// returned to the pool before ToBytes returns.
 func ToBytes[T any](obj *T, fn PackFn[T]) []byte {
-	buf := NewWriter()
+	buf := AcquireWriter()
+	defer ReleaseWriter(buf)
+
 	fn(obj, buf)
 	if buf.Error {
 		return nil
-	} else {
-		return buf.Data
 	}
+
+	result := make([]byte, len(buf.Data))
+	copy(result, buf.Data)
+	return result
 }
 
 func FromBytes[T any](data []byte, fn PackFn[T]) *T {
@@ -29,8 +41,36 @@ func FromBytes[T any](data []byte, fn PackFn[T]) *T {
 	}
 }
 
+// FromBytesInto unpacks data into obj using fn. It uses a pooled scratch
+// Buffer internally (see AcquireReader) so repeated calls don't each
+// allocate a new Buffer header.
 func FromBytesInto[T any](data []byte, obj *T, fn PackFn[T]) bool {
-	buf := NewReader(data)
+	buf := AcquireReader(data)
+	defer ReleaseReader(buf)
+
 	fn(obj, buf)
 	return !buf.Error
 }
+
+// ToBytesE is like ToBytes, but returns the *PackError recorded on the
+// buffer instead of silently returning nil on failure.
+func ToBytesE[T any](obj *T, fn PackFn[T]) ([]byte, error) {
+	buf := NewWriter()
+	fn(obj, buf)
+	if buf.Error {
+		return nil, buf.Err()
+	}
+	return buf.Data, nil
+}
+
+// FromBytesE is like FromBytes, but returns the *PackError recorded on the
+// buffer instead of silently returning nil on failure.
+func FromBytesE[T any](data []byte, fn PackFn[T]) (*T, error) {
+	var obj T
+	buf := NewReader(data)
+	fn(&obj, buf)
+	if buf.Error {
+		return nil, buf.Err()
+	}
+	return &obj, nil
+}
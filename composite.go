@@ -2,103 +2,102 @@ package vpack
 
 import (
 	"encoding"
+	"fmt"
 	"time"
 
 	"go.hasen.dev/generic"
 )
 
-// String implements serialization for a string by first writing out the length
-// in bytes (as a varint) then dumping the actual bytes into the buffer. When
-// deserializing, it starts by reading the length (as a varint) then taking a
-// slice of the input buffer and cloning it to a string
-func String(s *string, buf *Buffer) {
+// Field wraps fn so that any failure while (de)serializing ptr is recorded
+// with name added to the buffer's field path, giving PackError a breadcrumb
+// like `O1[1].S1` to point at exactly what was being processed. Combinators
+// like Slice and Map use it to tag each element with its index or key;
+// hand-written PackFns can use it the same way to tag named struct fields.
+func Field[T any](name string, ptr *T, fn PackFn[T], buf PackTarget) {
+	buf.PushField(name)
+	defer buf.PopField()
+	fn(ptr, buf)
+}
+
+// String implements serialization for a string by first writing out the
+// length in bytes (as a varint) then writing the actual bytes. When
+// deserializing, it starts by reading the length (as a varint) then reads
+// exactly that many bytes into a new string.
+func String(s *string, buf PackTarget) {
 	var size = len(*s)
 	Int(&size, buf)
-	if buf.Writing {
-		var pos = len(buf.Data)
-		buf.EnsureSpace(size)
-		copy(buf.Data[pos:pos+size], *s)
+	if buf.Writing() {
+		buf.WriteBytes([]byte(*s)...)
 	} else {
-		// ReadBytes generally returns a slice into the buffer, not a copy of the data
-		// But `string(...)` copies the data to a new buffer in memory, so we should be ok!
 		*s = string(buf.ReadBytes(size))
 	}
 }
 
 // StringZ implement serialization for a string using null-byte termination.
 // This allows is to be used in the key of a boltdb key
-func StringZ(s *string, buf *Buffer) {
-	if buf.Writing {
-		var pos = len(buf.Data)
-		var size = len(*s)
-		buf.EnsureSpace(size)
-		copy(buf.Data[pos:pos+size], *s)
+func StringZ(s *string, buf PackTarget) {
+	if buf.Writing() {
+		buf.WriteBytes([]byte(*s)...)
 		buf.WriteBytes(0)
 	} else {
-		var start = buf.Pos
-		var end = start
-		for end < len(buf.Data) && buf.Data[end] != 0 {
-			end++
+		var out []byte
+		for {
+			b, err := buf.ReadByte()
+			if err != nil {
+				buf.Fail(fmt.Errorf("%w: %v", ErrShortRead, err))
+				return
+			}
+			if b == 0 {
+				break
+			}
+			out = append(out, b)
 		}
-		buf.Pos = end + 1
-		*s = string(buf.Data[start:end])
+		*s = string(out)
 	}
 }
 
 // ByteSlice implements serialization for a byte slice. It's more or less just
 // like String.
-func ByteSlice(s *[]byte, buf *Buffer) {
+func ByteSlice(s *[]byte, buf PackTarget) {
 	var size = len(*s)
 	Int(&size, buf)
-	if buf.Writing {
-		var pos = len(buf.Data)
-		buf.EnsureSpace(size)
-		copy(buf.Data[pos:pos+size], *s)
+	if buf.Writing() {
+		buf.WriteBytes(*s...)
 	} else {
-		// ReadBytes generally returns a slice into the buffer, not a copy of the data
-		// we need to copy it out
+		// ReadBytes may return a slice into a shared backing buffer, not a
+		// copy of the data, so we need to copy it out
 		*s = make([]byte, size)
 		copy(*s, buf.ReadBytes(size))
 	}
 }
 
-/*
-// unfortunately not possible with the current generics system :(
-func ByteArray[N int](s *[N]byte, buf *Buffer) {
-	switch buf.Mode {
-	case Serialize:
-		buf.Data = append(buf.Data, *s[:])
-	case Deserialize:
-		// ReadBytes generally returns a slice into the buffer, not a copy of the data
-		// we need to copy it out
-		copy(*s, buf.ReadBytes(len(*s)))
-	}
-}
-*/
-
 // Slice is a helper for serialization a slice of some type, given its
 // serialization function. It starts by reading/writing the length of the slice,
 // then uses the provided serialization function to serialize each individual
 // item in the slice.
-func Slice[T any](list *[]T, fn PackFn[T], buf *Buffer) {
+//
+// Slice always calls fn for every element; it never substitutes a different
+// encoding based on T's shape. If you want the raw-memory-copy fast path for
+// a POD type instead, call PODSlice directly.
+func Slice[T any](list *[]T, fn PackFn[T], buf PackTarget) {
 	var size = len(*list)
 	Int(&size, buf)
-	if !buf.Writing {
+	if !buf.Writing() {
 		*list = make([]T, size)
 	}
 	for index := range *list {
 		var item = &(*list)[index]
-		fn(item, buf)
+		Field(fmt.Sprintf("[%d]", index), item, fn, buf)
 	}
 }
 
-func Map[K comparable, T any](m *map[K]T, keyFn PackFn[K], valFn PackFn[T], buf *Buffer) {
+func Map[K comparable, T any](m *map[K]T, keyFn PackFn[K], valFn PackFn[T], buf PackTarget) {
 	var size = len(*m)
 	Int(&size, buf)
-	if buf.Writing {
+	if buf.Writing() {
 		for key, val := range *m {
 			keyFn(&key, buf)
-			valFn(&val, buf)
+			Field(fmt.Sprintf("[%v]", key), &val, valFn, buf)
 		}
 	} else {
 		generic.InitMap(m)
@@ -106,7 +105,7 @@ func Map[K comparable, T any](m *map[K]T, keyFn PackFn[K], valFn PackFn[T], buf
 			var key K
 			var val T
 			keyFn(&key, buf)
-			valFn(&val, buf)
+			Field(fmt.Sprintf("[%d]", i), &val, valFn, buf)
 			(*m)[key] = val
 		}
 	}
@@ -119,11 +118,11 @@ type Binary interface {
 
 // BinaryMarshal implements serialization for an object that implements the
 // BinaryMarshaler and BinaryUnmarshaler interfaces from the standard library.
-func BinaryMarshal(b Binary, buf *Buffer) {
-	if buf.Writing {
+func BinaryMarshal(b Binary, buf PackTarget) {
+	if buf.Writing() {
 		var data, err = b.MarshalBinary()
 		if err != nil {
-			buf.Error = true
+			buf.Fail(fmt.Errorf("%w: %v", ErrBinaryMarshalFail, err))
 			return
 		}
 		ByteSlice(&data, buf)
@@ -132,7 +131,7 @@ func BinaryMarshal(b Binary, buf *Buffer) {
 		ByteSlice(&data, buf)
 		var err = b.UnmarshalBinary(data)
 		if err != nil {
-			buf.Error = true
+			buf.Fail(fmt.Errorf("%w: %v", ErrBinaryMarshalFail, err))
 			return
 		}
 	}
@@ -140,7 +139,7 @@ func BinaryMarshal(b Binary, buf *Buffer) {
 
 // Time implement serialization for the std library's Time object using the
 // Binary Marshalling interface
-func Time(t *time.Time, buf *Buffer) {
+func Time(t *time.Time, buf PackTarget) {
 	BinaryMarshal(t, buf)
 }
 
@@ -151,16 +150,16 @@ func Time(t *time.Time, buf *Buffer) {
 // It can store a reasonably accurate timestamp in 5 or 6 bytes.
 //
 // If you require subsecond accuracy, don't use this function.
-func UnixTime(t *time.Time, buf *Buffer) {
+func UnixTime(t *time.Time, buf PackTarget) {
 	var seconds int64
 
-	if buf.Writing {
+	if buf.Writing() {
 		seconds = t.Unix()
 	}
 
 	VInt64(&seconds, buf)
 
-	if !buf.Writing {
+	if !buf.Writing() {
 		*t = time.Unix(seconds, 0)
 	}
 }
@@ -169,48 +168,48 @@ func UnixTime(t *time.Time, buf *Buffer) {
 // suitable for a bucket key so we can iterate by timestamp
 //
 // If you require subsecond accuracy, don't use this function.
-func UnixTimeKey(t *time.Time, buf *Buffer) {
+func UnixTimeKey(t *time.Time, buf PackTarget) {
 	var seconds int64
 
-	if buf.Writing {
+	if buf.Writing() {
 		seconds = t.Unix()
 	}
 
 	FInt64(&seconds, buf)
 
-	if !buf.Writing {
+	if !buf.Writing() {
 		*t = time.Unix(seconds, 0)
 	}
 }
 
 // UnixTimeMilli is similar to UnixTime but truncates to the MilliSecond level
 // making it more suitable for cases where sub-second accuracy is required
-func UnixTimeMilli(t *time.Time, buf *Buffer) {
+func UnixTimeMilli(t *time.Time, buf PackTarget) {
 	var ms int64
 
-	if buf.Writing {
+	if buf.Writing() {
 		ms = t.UnixMilli()
 	}
 
 	VInt64(&ms, buf)
 
-	if !buf.Writing {
+	if !buf.Writing() {
 		*t = time.UnixMilli(ms)
 	}
 }
 
 // UnixTimeMilliKey is similar to UnixTimeMilli, but uses fixed encoding so the
 // value is suitable for a bucket key so we can iterate by timestamp
-func UnixTimeMilliKey(t *time.Time, buf *Buffer) {
+func UnixTimeMilliKey(t *time.Time, buf PackTarget) {
 	var ms int64
 
-	if buf.Writing {
+	if buf.Writing() {
 		ms = t.UnixMilli()
 	}
 
 	FInt64(&ms, buf)
 
-	if !buf.Writing {
+	if !buf.Writing() {
 		*t = time.UnixMilli(ms)
 	}
 }
@@ -0,0 +1,39 @@
+package vpack
+
+import "testing"
+
+// TestSliceAlwaysUsesProvidedFn guards against Slice silently substituting
+// PODSlice's raw-memory-copy encoding for a POD-shaped T: here fn
+// varint-encodes each field, so a Point{1, 2} must pack to 2 bytes (one
+// varint byte per field), not the 16 bytes a raw copy of two int64s would
+// take.
+func TestSliceAlwaysUsesProvidedFn(t *testing.T) {
+	type Point struct {
+		X, Y int64
+	}
+
+	packPoint := func(p *Point, buf PackTarget) {
+		VInt64(&p.X, buf)
+		VInt64(&p.Y, buf)
+	}
+
+	original := []Point{{1, 2}}
+	data := ToBytes(&original, func(list *[]Point, buf PackTarget) { Slice(list, packPoint, buf) })
+	if data == nil {
+		t.Fatal("packing failed")
+	}
+
+	// length-prefix varint (1 byte for size=1) + one varint byte per field
+	if len(data) != 3 {
+		t.Fatalf("expected Slice to use the provided varint fn (3 bytes), got %d bytes: %v", len(data), data)
+	}
+
+	var result []Point
+	ok := FromBytesInto(data, &result, func(list *[]Point, buf PackTarget) { Slice(list, packPoint, buf) })
+	if !ok {
+		t.Fatal("unpacking failed")
+	}
+	if len(result) != 1 || result[0] != original[0] {
+		t.Fatalf("round trip mismatch: got %+v, want %+v", result, original)
+	}
+}
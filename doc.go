@@ -104,4 +104,4 @@ Later at time t1, we remove the `Energy` field, and add a `Price` field.
 	    )
 	}
 */
-package store
+package vpack
@@ -0,0 +1,37 @@
+package vpack
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel causes wrapped by PackError. Use errors.Is to check for a
+// specific one, e.g. errors.Is(err, ErrShortRead).
+var (
+	ErrShortRead         = errors.New("short read")
+	ErrInvalidVarint     = errors.New("invalid varint")
+	ErrVersionOverflow   = errors.New("version overflow")
+	ErrBinaryMarshalFail = errors.New("BinaryMarshaler error")
+)
+
+// PackError is the structured error recorded on a Buffer when a pack
+// function fails, whether while reading or writing. It records where in the
+// stream the failure happened, what nested field was being (de)serialized
+// at the time, and the underlying cause.
+type PackError struct {
+	Op     string // "read" or "write"
+	Offset int    // buffer position when the failure occurred
+	Path   string // breadcrumb of field names, e.g. `O1[1].S1`
+	Cause  error
+}
+
+func (e *PackError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("pack: %s at offset %d: %v", e.Op, e.Offset, e.Cause)
+	}
+	return fmt.Sprintf("pack: %s at offset %d: field %q: %v", e.Op, e.Offset, e.Path, e.Cause)
+}
+
+func (e *PackError) Unwrap() error {
+	return e.Cause
+}
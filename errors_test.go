@@ -0,0 +1,71 @@
+package vpack
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFieldErrorPath(t *testing.T) {
+	type Other struct {
+		I1 int
+		S1 string
+	}
+	type Something struct {
+		O1 []Other
+	}
+
+	packOther := func(self *Other, buf PackTarget) {
+		Field("I1", &self.I1, Int, buf)
+		Field("S1", &self.S1, String, buf)
+	}
+	packSomething := func(self *Something, buf PackTarget) {
+		Slice(&self.O1, packOther, buf)
+	}
+
+	// Truncate the encoded data so reading S1's length byte runs out of
+	// room, forcing a short read underneath O1[1].S1.
+	obj := Something{O1: []Other{{I1: 1, S1: "a"}, {I1: 2, S1: "bb"}}}
+	data := ToBytes(&obj, packSomething)
+	data = data[:len(data)-1]
+
+	var result Something
+	ok := FromBytesInto(data, &result, packSomething)
+	if ok {
+		t.Fatal("expected unpacking to fail on truncated data")
+	}
+
+	buf := NewReader(data)
+	packSomething(&result, buf)
+	if buf.Err() == nil {
+		t.Fatal("expected a recorded PackError")
+	}
+	if !errors.Is(buf.Err(), ErrShortRead) {
+		t.Fatalf("expected ErrShortRead, got: %v", buf.Err())
+	}
+	if buf.Err().Path == "" {
+		t.Fatal("expected a non-empty field path")
+	}
+}
+
+func TestToBytesEFromBytesE(t *testing.T) {
+	var n int64 = 4242
+	packN := func(v *int64, buf PackTarget) { VInt64(v, buf) }
+
+	data, err := ToBytesE(&n, packN)
+	if err != nil {
+		t.Fatalf("ToBytesE failed: %v", err)
+	}
+
+	result, err := FromBytesE(data, packN)
+	if err != nil {
+		t.Fatalf("FromBytesE failed: %v", err)
+	}
+	if *result != n {
+		t.Fatalf("value mismatch: %d != %d", *result, n)
+	}
+
+	_, err = FromBytesE(data[:0], packN)
+	if err == nil {
+		t.Fatal("expected an error from empty data")
+	}
+}
@@ -0,0 +1,190 @@
+package vpack
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// cmdTagSize is the fixed width, in bytes, of the command tag in a framed
+// message header. Shorter commands are null-padded; longer ones are
+// rejected by WriteMessage.
+const cmdTagSize = 12
+
+// MaxMessageSize bounds the payload length accepted by ReadMessage/Dispatch,
+// guarding against a corrupt or hostile length field causing a runaway
+// allocation.
+const MaxMessageSize = 1 << 24 // 16 MiB
+
+var (
+	ErrCommandTooLong  = errors.New("vpack: command tag longer than 12 bytes")
+	ErrMagicMismatch   = errors.New("vpack: magic mismatch")
+	ErrMessageTooLarge = errors.New("vpack: message payload exceeds MaxMessageSize")
+	ErrMessageChecksum = errors.New("vpack: message payload failed checksum")
+	ErrMessageUnpack   = errors.New("vpack: message payload failed to unpack")
+	ErrUnknownCommand  = errors.New("vpack: no handler registered for command")
+)
+
+// messageHeaderSize is magic(4) + cmd(cmdTagSize) + length(4) + checksum(4).
+const messageHeaderSize = 4 + cmdTagSize + 4 + 4
+
+// WriteMessage writes obj as a single framed message to w: a 4-byte magic, a
+// fixed-width command tag, the payload length, a checksum (the first 4 bytes
+// of SHA-256 over the packed payload), then the payload itself as produced by
+// fn via ToBytes.
+//
+// magic lets a reader reject data from the wrong protocol/version before
+// trusting the rest of the header; cmd identifies which kind of payload
+// follows so a single stream can carry a mix of message types.
+func WriteMessage[T any](w io.Writer, magic uint32, cmd string, obj *T, fn PackFn[T]) error {
+	if len(cmd) > cmdTagSize {
+		return ErrCommandTooLong
+	}
+
+	body := ToBytes(obj, fn)
+	if body == nil {
+		return GenericError
+	}
+
+	var header [messageHeaderSize]byte
+	binary.BigEndian.PutUint32(header[0:4], magic)
+	copy(header[4:4+cmdTagSize], cmd)
+	binary.BigEndian.PutUint32(header[4+cmdTagSize:4+cmdTagSize+4], uint32(len(body)))
+	sum := sha256.Sum256(body)
+	copy(header[4+cmdTagSize+4:], sum[:4])
+
+	if _, err := w.Write(header[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(body)
+	return err
+}
+
+// ReadMessage reads a single framed message written by WriteMessage from r,
+// validating the magic, the payload length, and the checksum before handing
+// the payload to fn. It returns the message's command tag alongside any
+// error, so a caller that only cares about dispatching on it doesn't need to
+// decode the payload first.
+func ReadMessage[T any](r io.Reader, magic uint32, obj *T, fn PackFn[T]) (cmd string, err error) {
+	var header [messageHeaderSize]byte
+	if _, err = io.ReadFull(r, header[:]); err != nil {
+		return
+	}
+
+	gotMagic := binary.BigEndian.Uint32(header[0:4])
+	if gotMagic != magic {
+		err = ErrMagicMismatch
+		return
+	}
+
+	cmd = string(bytes.TrimRight(header[4:4+cmdTagSize], "\x00"))
+	length := binary.BigEndian.Uint32(header[4+cmdTagSize : 4+cmdTagSize+4])
+	if length > MaxMessageSize {
+		err = ErrMessageTooLarge
+		return
+	}
+	wantSum := header[4+cmdTagSize+4 : messageHeaderSize]
+
+	body := make([]byte, length)
+	if _, err = io.ReadFull(r, body); err != nil {
+		return
+	}
+
+	gotSum := sha256.Sum256(body)
+	if !bytes.Equal(gotSum[:4], wantSum) {
+		err = ErrMessageChecksum
+		return
+	}
+
+	if !FromBytesInto(body, obj, fn) {
+		err = ErrMessageUnpack
+		return
+	}
+	return
+}
+
+// Handler processes the verified payload of a dispatched message. Handlers
+// decode the payload themselves (typically via FromBytesInto with the
+// PackFn registered for that command), since a single map can't hold
+// PackFn[T] values for varying T.
+type Handler func(payload []byte) error
+
+// CommandRegistry maps a framed message's command tag to the Handler that
+// decodes and processes it, so Dispatch can be driven directly off
+// registrations made via Register instead of requiring every caller to
+// hand-assemble a map[string]Handler of their own.
+type CommandRegistry struct {
+	handlers map[string]Handler
+}
+
+// NewCommandRegistry returns an empty CommandRegistry ready for Register
+// calls.
+func NewCommandRegistry() *CommandRegistry {
+	return &CommandRegistry{handlers: make(map[string]Handler)}
+}
+
+// Register adds cmd to reg: a dispatched message tagged cmd is decoded with
+// fn, then handle is called with the decoded value. Register is a free
+// function rather than a CommandRegistry method because Go generics can't
+// express a type parameter on a method; it closes over T to build the
+// untyped Handler the registry actually stores.
+func Register[T any](reg *CommandRegistry, cmd string, fn PackFn[T], handle func(*T) error) {
+	reg.handlers[cmd] = func(payload []byte) error {
+		var obj T
+		if !FromBytesInto(payload, &obj, fn) {
+			return ErrMessageUnpack
+		}
+		return handle(&obj)
+	}
+}
+
+// Dispatch reads framed messages from r in a loop, verifying each one's
+// magic and checksum, and invokes the Handler registered under its command
+// tag in reg. It returns nil when r is exhausted, or the first error
+// encountered reading a header/payload, failing a checksum, or returned by a
+// handler.
+func Dispatch(r io.Reader, magic uint32, reg *CommandRegistry) error {
+	for {
+		var header [messageHeaderSize]byte
+		_, err := io.ReadFull(r, header[:])
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		gotMagic := binary.BigEndian.Uint32(header[0:4])
+		if gotMagic != magic {
+			return ErrMagicMismatch
+		}
+
+		cmd := string(bytes.TrimRight(header[4:4+cmdTagSize], "\x00"))
+		length := binary.BigEndian.Uint32(header[4+cmdTagSize : 4+cmdTagSize+4])
+		if length > MaxMessageSize {
+			return ErrMessageTooLarge
+		}
+		wantSum := header[4+cmdTagSize+4 : messageHeaderSize]
+
+		body := make([]byte, length)
+		if _, err := io.ReadFull(r, body); err != nil {
+			return err
+		}
+
+		gotSum := sha256.Sum256(body)
+		if !bytes.Equal(gotSum[:4], wantSum) {
+			return ErrMessageChecksum
+		}
+
+		handler, ok := reg.handlers[cmd]
+		if !ok {
+			return fmt.Errorf("%w: %q", ErrUnknownCommand, cmd)
+		}
+		if err := handler(body); err != nil {
+			return err
+		}
+	}
+}
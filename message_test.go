@@ -0,0 +1,84 @@
+package vpack
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMessageRoundTrip(t *testing.T) {
+	type Ping struct {
+		Seq int
+		Msg string
+	}
+
+	PackPing := func(self *Ping, buf PackTarget) {
+		Int(&self.Seq, buf)
+		String(&self.Msg, buf)
+	}
+
+	const magic = 0xC0FFEE
+
+	var out bytes.Buffer
+	send := Ping{Seq: 7, Msg: "hello"}
+	if err := WriteMessage(&out, magic, "ping", &send, PackPing); err != nil {
+		t.Fatalf("WriteMessage failed: %v", err)
+	}
+
+	var recv Ping
+	cmd, err := ReadMessage(&out, magic, &recv, PackPing)
+	if err != nil {
+		t.Fatalf("ReadMessage failed: %v", err)
+	}
+	if cmd != "ping" {
+		t.Fatalf("cmd mismatch: got %q", cmd)
+	}
+	if recv != send {
+		t.Fatalf("objects don't match: %+v != %+v", recv, send)
+	}
+}
+
+func TestMessageBadMagic(t *testing.T) {
+	type Ping struct{ Seq int }
+	PackPing := func(self *Ping, buf PackTarget) { Int(&self.Seq, buf) }
+
+	var out bytes.Buffer
+	send := Ping{Seq: 1}
+	if err := WriteMessage(&out, 0x1111, "ping", &send, PackPing); err != nil {
+		t.Fatalf("WriteMessage failed: %v", err)
+	}
+
+	var recv Ping
+	_, err := ReadMessage(&out, 0x2222, &recv, PackPing)
+	if err != ErrMagicMismatch {
+		t.Fatalf("expected ErrMagicMismatch, got %v", err)
+	}
+}
+
+func TestDispatch(t *testing.T) {
+	type Ping struct{ Seq int }
+	PackPing := func(self *Ping, buf PackTarget) { Int(&self.Seq, buf) }
+
+	const magic = 0xBEEF
+
+	var out bytes.Buffer
+	for i := 0; i < 3; i++ {
+		p := Ping{Seq: i}
+		if err := WriteMessage(&out, magic, "ping", &p, PackPing); err != nil {
+			t.Fatalf("WriteMessage failed: %v", err)
+		}
+	}
+
+	var seqs []int
+	reg := NewCommandRegistry()
+	Register(reg, "ping", PackPing, func(p *Ping) error {
+		seqs = append(seqs, p.Seq)
+		return nil
+	})
+
+	if err := Dispatch(&out, magic, reg); err != nil {
+		t.Fatalf("Dispatch failed: %v", err)
+	}
+	if len(seqs) != 3 || seqs[0] != 0 || seqs[1] != 1 || seqs[2] != 2 {
+		t.Fatalf("unexpected seqs: %v", seqs)
+	}
+}
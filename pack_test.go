@@ -24,7 +24,7 @@ func TestPackingThings(t *testing.T) {
 		S1 string
 	}
 
-	PackOther := func(self *Other, buf *Buffer) {
+	PackOther := func(self *Other, buf PackTarget) {
 		Int(&self.I1, buf)
 		String(&self.S1, buf)
 	}
@@ -39,7 +39,7 @@ func TestPackingThings(t *testing.T) {
 		T1 time.Time
 	}
 
-	PackSomething := func(self *Something, buf *Buffer) {
+	PackSomething := func(self *Something, buf PackTarget) {
 		Int(&self.I1, buf)
 		Int(&self.I2, buf)
 		String(&self.S1, buf)
@@ -0,0 +1,189 @@
+package vpack
+
+import (
+	"reflect"
+	"sync"
+	"unsafe"
+)
+
+// podCache memoizes the result of IsPODZeroCopy per type so the reflection
+// walk only happens once per T.
+var podCache sync.Map // map[reflect.Type]bool
+
+// hostIsLittleEndian is computed once at init. PODSlice's on-disk format is
+// always little endian (documented below), so on a big endian host it must
+// fall back to the per-field path instead of reinterpreting memory directly.
+var hostIsLittleEndian = func() bool {
+	var x uint16 = 1
+	return *(*byte)(unsafe.Pointer(&x)) == 1
+}()
+
+// IsPODZeroCopy reports whether T is "plain old data": a fixed-size
+// primitive, an array of such, or a struct made up only of such fields with
+// no compiler-inserted padding between them. Types that satisfy this can be
+// (de)serialized by copying their in-memory bytes directly instead of
+// walking them field by field, which is what PODSlice relies on.
+//
+// The result is computed once per type via reflection and cached, so callers
+// can call this on a hot path without worrying about repeated reflection
+// cost.
+func IsPODZeroCopy[T any]() bool {
+	var t T
+	typ := reflect.TypeOf(t)
+	if typ == nil {
+		return false
+	}
+	if cached, ok := podCache.Load(typ); ok {
+		return cached.(bool)
+	}
+	result := isPODZeroCopyType(typ)
+	podCache.Store(typ, result)
+	return result
+}
+
+func isPODZeroCopyType(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Bool,
+		reflect.Int8, reflect.Uint8,
+		reflect.Int16, reflect.Uint16,
+		reflect.Int32, reflect.Uint32,
+		reflect.Int64, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+
+	// reflect.Int and reflect.Uint are deliberately excluded: Go's int/uint
+	// width is platform-dependent (4 bytes on 32-bit, 8 on 64-bit), so a
+	// raw-memory copy of one isn't portable across architectures the way
+	// PODSlice's doc comment promises. Every other use of int/uint in this
+	// package goes through the varint-encoded Int/UInt for the same reason.
+
+	case reflect.Array:
+		return isPODZeroCopyType(typ.Elem())
+
+	case reflect.Struct:
+		var offset uintptr
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if field.Offset != offset {
+				return false // compiler inserted padding before this field
+			}
+			if !isPODZeroCopyType(field.Type) {
+				return false
+			}
+			offset += field.Type.Size()
+		}
+		return offset == typ.Size() // no trailing padding either
+	}
+	return false
+}
+
+// PODSlice serializes a slice of a POD type T by copying its backing array
+// directly to/from the buffer, bypassing the per-element PackFn call that
+// Slice would otherwise make. Callers should guard calls to it with
+// IsPODZeroCopy[T](); types that aren't zero-copy-safe must use Slice with an
+// explicit per-field PackFn instead.
+//
+// The on-disk layout is length-prefixed (like Slice) followed by the raw
+// little-endian bytes of the elements. On a big endian host, PODSlice falls
+// back to copying element-by-element, byte-swapping each primitive field in
+// place (not the element as a whole, which would scramble multi-field
+// structs) so files stay portable across architectures.
+func PODSlice[T any](s *[]T, buf PackTarget) {
+	var size = len(*s)
+	Int(&size, buf)
+
+	var zero T
+	elemSize := int(unsafe.Sizeof(zero))
+
+	if buf.Writing() {
+		if size == 0 {
+			return
+		}
+		if hostIsLittleEndian {
+			raw := unsafe.Slice((*byte)(unsafe.Pointer(&(*s)[0])), size*elemSize)
+			buf.WriteBytes(raw...)
+		} else {
+			spans := podFieldSpans(reflect.TypeOf(zero))
+			tmp := make([]byte, elemSize)
+			for i := range *s {
+				raw := unsafe.Slice((*byte)(unsafe.Pointer(&(*s)[i])), elemSize)
+				copy(tmp, raw)
+				swapFieldBytes(tmp, spans)
+				buf.WriteBytes(tmp...)
+			}
+		}
+		return
+	}
+
+	*s = make([]T, size)
+	if size == 0 {
+		return
+	}
+	if hostIsLittleEndian {
+		raw := buf.ReadBytes(size * elemSize)
+		dst := unsafe.Slice((*byte)(unsafe.Pointer(&(*s)[0])), size*elemSize)
+		copy(dst, raw)
+	} else {
+		spans := podFieldSpans(reflect.TypeOf(zero))
+		for i := range *s {
+			raw := buf.ReadBytes(elemSize)
+			dst := unsafe.Slice((*byte)(unsafe.Pointer(&(*s)[i])), elemSize)
+			copy(dst, raw)
+			swapFieldBytes(dst, spans)
+		}
+	}
+}
+
+// podFieldSpan is the offset and size, in bytes, of one primitive leaf field
+// within a POD type's in-memory layout.
+type podFieldSpan struct {
+	offset uintptr
+	size   int
+}
+
+// podSpansCache memoizes podFieldSpans's reflection walk per type.
+var podSpansCache sync.Map // map[reflect.Type][]podFieldSpan
+
+// podFieldSpans returns the offset/size of every primitive leaf field in typ,
+// so a big endian host can byte-swap each one individually instead of
+// reversing a whole multi-field element's bytes (which would put the fields
+// themselves in the wrong order as well as each field's bytes).
+func podFieldSpans(typ reflect.Type) []podFieldSpan {
+	if cached, ok := podSpansCache.Load(typ); ok {
+		return cached.([]podFieldSpan)
+	}
+	var spans []podFieldSpan
+	collectPODFieldSpans(typ, 0, &spans)
+	actual, _ := podSpansCache.LoadOrStore(typ, spans)
+	return actual.([]podFieldSpan)
+}
+
+func collectPODFieldSpans(typ reflect.Type, base uintptr, out *[]podFieldSpan) {
+	switch typ.Kind() {
+	case reflect.Array:
+		elem := typ.Elem()
+		elemSize := elem.Size()
+		for i := 0; i < typ.Len(); i++ {
+			collectPODFieldSpans(elem, base+uintptr(i)*elemSize, out)
+		}
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			collectPODFieldSpans(field.Type, base+field.Offset, out)
+		}
+	default:
+		*out = append(*out, podFieldSpan{offset: base, size: int(typ.Size())})
+	}
+}
+
+// swapFieldBytes reverses the bytes of each span in b in place, byte-swapping
+// every primitive field of a POD element without disturbing the order the
+// fields themselves appear in.
+func swapFieldBytes(b []byte, spans []podFieldSpan) {
+	for _, sp := range spans {
+		seg := b[sp.offset : sp.offset+uintptr(sp.size)]
+		for i, j := 0, len(seg)-1; i < j; i, j = i+1, j-1 {
+			seg[i], seg[j] = seg[j], seg[i]
+		}
+	}
+}
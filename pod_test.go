@@ -0,0 +1,105 @@
+package vpack
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+func TestIsPODZeroCopy(t *testing.T) {
+	type Padded struct {
+		A byte
+		B int64 // introduces 7 bytes of padding before it
+	}
+	type Packed struct {
+		A int64
+		B int64
+	}
+
+	if IsPODZeroCopy[uint64]() != true {
+		t.Fatal("uint64 should be POD zero-copy")
+	}
+	if IsPODZeroCopy[Packed]() != true {
+		t.Fatal("Packed should be POD zero-copy")
+	}
+	if IsPODZeroCopy[Padded]() != false {
+		t.Fatal("Padded should not be POD zero-copy")
+	}
+	if IsPODZeroCopy[string]() != false {
+		t.Fatal("string should not be POD zero-copy")
+	}
+	// int/uint have a platform-dependent width (4 bytes on 32-bit, 8 on
+	// 64-bit), so a raw-memory copy of one isn't portable across
+	// architectures; they must not be treated as POD zero-copy.
+	if IsPODZeroCopy[int]() != false {
+		t.Fatal("int should not be POD zero-copy (platform-dependent width)")
+	}
+	if IsPODZeroCopy[uint]() != false {
+		t.Fatal("uint should not be POD zero-copy (platform-dependent width)")
+	}
+	type HasIntField struct {
+		A int64
+		B int
+	}
+	if IsPODZeroCopy[HasIntField]() != false {
+		t.Fatal("a struct with an int field should not be POD zero-copy")
+	}
+}
+
+func TestPODSliceRoundTrip(t *testing.T) {
+	type Point struct {
+		X, Y int64
+	}
+
+	original := []Point{{1, 2}, {3, 4}, {5, 6}}
+
+	data := ToBytes(&original, func(s *[]Point, buf PackTarget) {
+		PODSlice(s, buf)
+	})
+	if data == nil {
+		t.Fatal("packing failed")
+	}
+
+	var result []Point
+	ok := FromBytesInto(data, &result, func(s *[]Point, buf PackTarget) {
+		PODSlice(s, buf)
+	})
+	if !ok {
+		t.Fatal("unpacking failed")
+	}
+
+	if len(result) != len(original) {
+		t.Fatalf("length mismatch: %d != %d", len(result), len(original))
+	}
+	for i := range original {
+		if result[i] != original[i] {
+			t.Fatalf("element %d mismatch: %+v != %+v", i, result[i], original[i])
+		}
+	}
+}
+
+// TestSwapFieldBytesPerField guards against the big endian fallback
+// reversing a multi-field element's bytes as one block, which would put the
+// fields themselves in the wrong order as well as byte-swapping them.
+func TestSwapFieldBytesPerField(t *testing.T) {
+	type Pair struct {
+		X, Y uint32
+	}
+
+	spans := podFieldSpans(reflect.TypeOf(Pair{}))
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 field spans, got %d", len(spans))
+	}
+
+	b := []byte{
+		0x00, 0x00, 0x00, 0x01, // X = 1, big endian
+		0x00, 0x00, 0x00, 0x02, // Y = 2, big endian
+	}
+	swapFieldBytes(b, spans)
+
+	gotX := binary.BigEndian.Uint32(b[0:4])
+	gotY := binary.BigEndian.Uint32(b[4:8])
+	if gotX != 1<<24 || gotY != 2<<24 {
+		t.Fatalf("expected each field byte-swapped independently, got X=%#x Y=%#x", gotX, gotY)
+	}
+}
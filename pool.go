@@ -0,0 +1,93 @@
+package vpack
+
+// maxPooledBuffers bounds how many *Buffer instances the free lists hold at
+// once, mirroring the bounded-channel free list pattern used by btcd's
+// binaryFreeList: a fixed-capacity channel used as a non-blocking pool.
+const maxPooledBuffers = 1024
+
+// maxPooledBufferCap bounds how large a pooled Buffer's backing array can be.
+// A Buffer that grew past this while handling one oversized message is
+// dropped instead of recycled, so the free list doesn't end up pinning a
+// handful of oversized allocations forever.
+const maxPooledBufferCap = 1 << 16 // 64 KiB
+
+type bufferFreeList chan *Buffer
+
+var writerFreeList bufferFreeList = make(chan *Buffer, maxPooledBuffers)
+var readerFreeList bufferFreeList = make(chan *Buffer, maxPooledBuffers)
+
+// AcquireWriter returns a *Buffer ready for serialization, reusing a
+// previously released buffer's backing array when one is available. Callers
+// must pass the buffer to ReleaseWriter when they're done with it; failing
+// to do so just forgoes reuse, it doesn't leak anything.
+func AcquireWriter() *Buffer {
+	select {
+	case buf := <-writerFreeList:
+		buf.Data = buf.Data[:0]
+		buf.Pos = 0
+		buf.Error = false
+		buf.err = nil
+		buf.path = buf.path[:0]
+		buf.Mode = Serialize
+		return buf
+	default:
+		return NewWriter()
+	}
+}
+
+// ReleaseWriter returns buf to the writer free list for reuse by a future
+// AcquireWriter call. Buffers whose backing array has grown past
+// maxPooledBufferCap are dropped instead.
+func ReleaseWriter(buf *Buffer) {
+	if cap(buf.Data) > maxPooledBufferCap {
+		return
+	}
+	select {
+	case writerFreeList <- buf:
+	default: // free list is full; let buf be garbage collected
+	}
+}
+
+// AcquireReader returns a *Buffer ready for deserializing data, reusing a
+// previously released buffer when one is available. The caller owns data;
+// it is not copied. Callers must pass the buffer to ReleaseReader when
+// they're done reading from it.
+func AcquireReader(data []byte) *Buffer {
+	select {
+	case buf := <-readerFreeList:
+		buf.Data = data
+		buf.Pos = 0
+		buf.Error = false
+		buf.err = nil
+		buf.path = buf.path[:0]
+		buf.Mode = Deserialize
+		return buf
+	default:
+		return NewReader(data)
+	}
+}
+
+// ReleaseReader returns buf to the reader free list for reuse by a future
+// AcquireReader call. The backing data slice is cleared first so the pool
+// doesn't keep the caller's data alive.
+func ReleaseReader(buf *Buffer) {
+	buf.Data = nil
+	select {
+	case readerFreeList <- buf:
+	default: // free list is full; let buf be garbage collected
+	}
+}
+
+// ToBytesInto packs obj using fn, appending the encoded bytes to dst and
+// returning the resulting slice. Unlike ToBytes, it never allocates a fresh
+// backing array itself: callers that reuse dst across many calls (e.g. a
+// scratch buffer in a bolt Put loop) avoid the per-call allocation ToBytes
+// otherwise pays for.
+func ToBytesInto[T any](dst []byte, obj *T, fn PackFn[T]) []byte {
+	buf := &Buffer{Data: dst, Mode: Serialize}
+	fn(obj, buf)
+	if buf.Error {
+		return nil
+	}
+	return buf.Data
+}
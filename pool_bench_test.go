@@ -0,0 +1,108 @@
+package vpack
+
+import "testing"
+
+func BenchmarkVInt64(b *testing.B) {
+	var n int64 = 123456789
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf := NewWriter()
+		VInt64(&n, buf)
+	}
+}
+
+func BenchmarkString(b *testing.B) {
+	s := "the quick brown fox jumps over the lazy dog"
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf := NewWriter()
+		String(&s, buf)
+	}
+}
+
+func BenchmarkSlice(b *testing.B) {
+	list := make([]uint64, 256)
+	for i := range list {
+		list[i] = uint64(i)
+	}
+	packFn := func(n *uint64, buf PackTarget) { FUInt64(n, buf) }
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf := NewWriter()
+		Slice(&list, packFn, buf)
+	}
+}
+
+func BenchmarkToBytes(b *testing.B) {
+	type Other struct {
+		I1 int
+		S1 string
+	}
+	type Something struct {
+		I1 int
+		I2 int
+		S1 string
+		O1 []Other
+	}
+
+	packOther := func(self *Other, buf PackTarget) {
+		Int(&self.I1, buf)
+		String(&self.S1, buf)
+	}
+	packSomething := func(self *Something, buf PackTarget) {
+		Int(&self.I1, buf)
+		Int(&self.I2, buf)
+		String(&self.S1, buf)
+		Slice(&self.O1, packOther, buf)
+	}
+
+	obj := Something{
+		I1: 100,
+		I2: 43222,
+		S1: "Hello, World!",
+		O1: []Other{{I1: 10}, {S1: "k"}},
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ToBytes(&obj, packSomething)
+	}
+}
+
+func BenchmarkToBytesInto(b *testing.B) {
+	type Other struct {
+		I1 int
+		S1 string
+	}
+	type Something struct {
+		I1 int
+		I2 int
+		S1 string
+		O1 []Other
+	}
+
+	packOther := func(self *Other, buf PackTarget) {
+		Int(&self.I1, buf)
+		String(&self.S1, buf)
+	}
+	packSomething := func(self *Something, buf PackTarget) {
+		Int(&self.I1, buf)
+		Int(&self.I2, buf)
+		String(&self.S1, buf)
+		Slice(&self.O1, packOther, buf)
+	}
+
+	obj := Something{
+		I1: 100,
+		I2: 43222,
+		S1: "Hello, World!",
+		O1: []Other{{I1: 10}, {S1: "k"}},
+	}
+
+	scratch := make([]byte, 0, 64)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		scratch = ToBytesInto(scratch[:0], &obj, packSomething)
+	}
+}
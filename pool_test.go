@@ -0,0 +1,37 @@
+package vpack
+
+import "testing"
+
+func TestAcquireWriterClearsStaleError(t *testing.T) {
+	buf := AcquireWriter()
+	buf.Fail(ErrShortRead)
+	if buf.Err() == nil {
+		t.Fatal("expected Fail to record an error")
+	}
+	ReleaseWriter(buf)
+
+	buf = AcquireWriter()
+	if buf.Err() != nil {
+		t.Fatalf("recycled writer should start with a clean Err(), got %v", buf.Err())
+	}
+	if buf.Error {
+		t.Fatal("recycled writer should start with Error false")
+	}
+}
+
+func TestAcquireReaderClearsStaleError(t *testing.T) {
+	buf := AcquireReader(nil)
+	buf.Fail(ErrShortRead)
+	if buf.Err() == nil {
+		t.Fatal("expected Fail to record an error")
+	}
+	ReleaseReader(buf)
+
+	buf = AcquireReader([]byte{1, 2, 3})
+	if buf.Err() != nil {
+		t.Fatalf("recycled reader should start with a clean Err(), got %v", buf.Err())
+	}
+	if buf.Error {
+		t.Fatal("recycled reader should start with Error false")
+	}
+}
@@ -2,6 +2,7 @@ package vpack
 
 import (
 	"encoding/binary"
+	"fmt"
 	"math"
 )
 
@@ -9,9 +10,11 @@ var BigEndian = binary.BigEndian
 
 // FUInt64 implements fixed size serialization of uint64. It writes data in big
 // endian, making it suitable for int keys to bolt.
-func FUInt64(n *uint64, buf *Buffer) {
-	if buf.Writing {
-		buf.Data = BigEndian.AppendUint64(buf.Data, *n)
+func FUInt64(n *uint64, buf PackTarget) {
+	if buf.Writing() {
+		var tmp [8]byte
+		BigEndian.PutUint64(tmp[:], *n)
+		buf.WriteBytes(tmp[:]...)
 	} else {
 		slice := buf.ReadBytes(8)
 		*n = BigEndian.Uint64(slice)
@@ -20,20 +23,24 @@ func FUInt64(n *uint64, buf *Buffer) {
 
 // FUInt32 implements fixed size serialization of uint32. It writes data in big
 // endian, making it suitable for int keys to bolt.
-func FUInt32(n *uint32, buf *Buffer) {
-	if buf.Writing {
-		buf.Data = BigEndian.AppendUint32(buf.Data, *n)
+func FUInt32(n *uint32, buf PackTarget) {
+	if buf.Writing() {
+		var tmp [4]byte
+		BigEndian.PutUint32(tmp[:], *n)
+		buf.WriteBytes(tmp[:]...)
 	} else {
-		slice := buf.ReadBytes(2)
+		slice := buf.ReadBytes(4)
 		*n = BigEndian.Uint32(slice)
 	}
 }
 
 // FUInt16 implements fixed size serialization of uint16. It writes data in big
 // endian, making it suitable for int keys to bolt.
-func FUInt16(n *uint16, buf *Buffer) {
-	if buf.Writing {
-		buf.Data = BigEndian.AppendUint16(buf.Data, *n)
+func FUInt16(n *uint16, buf PackTarget) {
+	if buf.Writing() {
+		var tmp [2]byte
+		BigEndian.PutUint16(tmp[:], *n)
+		buf.WriteBytes(tmp[:]...)
 	} else {
 		slice := buf.ReadBytes(2)
 		*n = BigEndian.Uint16(slice)
@@ -42,7 +49,7 @@ func FUInt16(n *uint16, buf *Buffer) {
 
 // FInt64 implements fixed size serialization of int64. It writes data in big
 // endian, making it suitable for int keys to bolt.
-func FInt64(n *int64, buf *Buffer) {
+func FInt64(n *int64, buf PackTarget) {
 	var u = uint64(*n)
 	FUInt64(&u, buf)
 	*n = int64(u)
@@ -50,29 +57,22 @@ func FInt64(n *int64, buf *Buffer) {
 
 // FInt implements fixed size serialization of int (as 64 bits). It writes data
 // in big endian, making it suitable for int keys to bolt.
-func FInt(n *int, buf *Buffer) {
+func FInt(n *int, buf PackTarget) {
 	var u = uint64(*n)
 	FUInt64(&u, buf)
 	*n = int(u)
 }
 
-func Float64(n *float64, buf *Buffer) {
+func Float64(n *float64, buf PackTarget) {
 	// Flaot64bit and Float64frombits are just transmute casts that should cost nothing
 	var u = math.Float64bits(*n)
 	FUInt64(&u, buf)
 	*n = math.Float64frombits(u)
 }
 
-/*
-	switch buf.Mode {
-	case Serialize:
-	case Deserialize:
-	}
-*/
-
 // Byte implements serialization for a single byte
-func Byte(b *byte, buf *Buffer) {
-	if buf.Writing {
+func Byte(b *byte, buf PackTarget) {
+	if buf.Writing() {
 		buf.WriteBytes(*b)
 	} else {
 		*b = buf.ReadBytes(1)[0]
@@ -80,7 +80,7 @@ func Byte(b *byte, buf *Buffer) {
 }
 
 // Bool implements serialization for a bool
-func Bool(b *bool, buf *Buffer) {
+func Bool(b *bool, buf PackTarget) {
 	var bt byte
 	if *b {
 		bt = 1
@@ -91,35 +91,39 @@ func Bool(b *bool, buf *Buffer) {
 
 // VInt64 implements varint encoding for int64. Varint users fewer bytes for
 // small values.
-func VInt64(n *int64, buf *Buffer) {
-	if buf.Writing {
-		buf.Data = binary.AppendVarint(buf.Data, *n)
+func VInt64(n *int64, buf PackTarget) {
+	if buf.Writing() {
+		var tmp [binary.MaxVarintLen64]byte
+		written := binary.PutVarint(tmp[:], *n)
+		buf.WriteBytes(tmp[:written]...)
 	} else {
 		var err error
 		*n, err = binary.ReadVarint(buf)
 		if err != nil {
-			buf.Error = true
+			buf.Fail(fmt.Errorf("%w: %v", ErrInvalidVarint, err))
 		}
 	}
 }
 
 // VUInt64 implements varint encoding for uin64. Varint users fewer bytes for
 // small values.
-func VUInt64(n *uint64, buf *Buffer) {
-	if buf.Writing {
-		buf.Data = binary.AppendUvarint(buf.Data, *n)
+func VUInt64(n *uint64, buf PackTarget) {
+	if buf.Writing() {
+		var tmp [binary.MaxVarintLen64]byte
+		written := binary.PutUvarint(tmp[:], *n)
+		buf.WriteBytes(tmp[:written]...)
 	} else {
 		var err error
 		*n, err = binary.ReadUvarint(buf)
 		if err != nil {
-			buf.Error = true
+			buf.Fail(fmt.Errorf("%w: %v", ErrInvalidVarint, err))
 		}
 	}
 }
 
 // Int implements varint encoding for int (as int64). Varint users fewer bytes for
 // small values.
-func Int(n *int, buf *Buffer) {
+func Int(n *int, buf PackTarget) {
 	var n64 = int64(*n)
 	VInt64(&n64, buf)
 	*n = int(n64)
@@ -127,7 +131,7 @@ func Int(n *int, buf *Buffer) {
 
 // UInt implements varint encoding for uint (as uint64). Varint users fewer
 // bytes for small values.
-func UInt(n *uint, buf *Buffer) {
+func UInt(n *uint, buf PackTarget) {
 	var n64 = uint64(*n)
 	VUInt64(&n64, buf)
 	*n = uint(n64)
@@ -138,24 +142,24 @@ type IntBased interface {
 }
 
 // IntEnum implements varint encoding for an int (or int64) based enum types
-func IntEnum[T IntBased](n *T, buf *Buffer) {
+func IntEnum[T IntBased](n *T, buf PackTarget) {
 	var n64 = int64(*n)
 	VInt64(&n64, buf)
 	*n = T(n64)
 }
 
 // Rune implements serialization for a single rune as a varint.
-func Rune(r *rune, buf *Buffer) {
+func Rune(r *rune, buf PackTarget) {
 	var n64 = int64(*r)
 	VInt64(&n64, buf)
 	*r = rune(n64)
 }
 
-func Version(max int, buf *Buffer) int {
+func Version(max int, buf PackTarget) int {
 	var v = max
 	Int(&v, buf)
 	if v > max {
-		buf.Error = true
+		buf.Fail(fmt.Errorf("%w: got version %d, max supported is %d", ErrVersionOverflow, v, max))
 	}
 	return v
 }
@@ -0,0 +1,24 @@
+package vpack
+
+import "testing"
+
+func TestFUInt32RoundTrip(t *testing.T) {
+	var n uint32 = 0xAABBCCDD
+
+	data := ToBytes(&n, func(v *uint32, buf PackTarget) { FUInt32(v, buf) })
+	if data == nil {
+		t.Fatal("packing failed")
+	}
+	if len(data) != 4 {
+		t.Fatalf("expected 4 bytes, got %d", len(data))
+	}
+
+	var result uint32
+	ok := FromBytesInto(data, &result, func(v *uint32, buf PackTarget) { FUInt32(v, buf) })
+	if !ok {
+		t.Fatal("unpacking failed")
+	}
+	if result != n {
+		t.Fatalf("value mismatch: got %#x, want %#x", result, n)
+	}
+}
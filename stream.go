@@ -0,0 +1,157 @@
+package vpack
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// PackTarget is what a PackFn actually reads from or writes to. *Buffer
+// implements it for in-memory (de)serialization; StreamBuffer implements it
+// for serializing directly to an io.Writer, or deserializing directly from
+// an io.Reader, without materializing the whole payload in memory first.
+type PackTarget interface {
+	// Writing reports whether the target is serializing (true) or
+	// deserializing (false).
+	Writing() bool
+
+	// ReadByte reads a single byte. It also makes PackTarget satisfy
+	// io.ByteReader, which binary.ReadVarint/ReadUvarint require.
+	ReadByte() (byte, error)
+
+	// ReadBytes reads exactly n bytes. If fewer are available, it fails the
+	// target (see Fail) and still returns a slice of length n.
+	ReadBytes(n int) []byte
+
+	// WriteBytes writes data to the target.
+	WriteBytes(data ...byte)
+
+	// EnsureSpace hints that n more bytes are about to be written, letting
+	// slice-backed targets grow their backing array once instead of on
+	// every append. Streaming targets can treat it as a no-op.
+	EnsureSpace(n int)
+
+	// Fail records cause as the reason (de)serialization failed, tagged
+	// with the current field path and position. Only the first failure is
+	// kept.
+	Fail(cause error)
+
+	// PushField/PopField track the breadcrumb of field names used to build
+	// a PackError's Path. See Field for a wrapper that pairs them.
+	PushField(name string)
+	PopField()
+}
+
+// StreamBuffer implements PackTarget against an io.Reader or io.Writer, so a
+// PackFn can serialize directly to a file or socket, or deserialize
+// record-by-record from one, without holding the whole encoded payload in
+// memory at once.
+type StreamBuffer struct {
+	r       *bufio.Reader
+	w       io.Writer
+	writing bool
+	pos     int
+
+	err  *PackError
+	path []string
+}
+
+// NewStreamWriter prepares a StreamBuffer that writes packed data straight
+// to w as it's produced.
+func NewStreamWriter(w io.Writer) *StreamBuffer {
+	return &StreamBuffer{w: w, writing: true}
+}
+
+// NewStreamReader prepares a StreamBuffer that reads packed data straight
+// from r. r is wrapped in a bufio.Reader internally so byte-at-a-time reads
+// (e.g. varint decoding) don't each turn into a separate read on r.
+func NewStreamReader(r io.Reader) *StreamBuffer {
+	return &StreamBuffer{r: bufio.NewReader(r)}
+}
+
+func (s *StreamBuffer) Writing() bool {
+	return s.writing
+}
+
+func (s *StreamBuffer) ReadByte() (byte, error) {
+	b, err := s.r.ReadByte()
+	if err == nil {
+		s.pos++
+	}
+	return b, err
+}
+
+func (s *StreamBuffer) ReadBytes(n int) []byte {
+	result := make([]byte, n)
+	read, err := io.ReadFull(s.r, result)
+	s.pos += read
+	if err != nil {
+		s.Fail(fmt.Errorf("%w (need %d, have %d)", ErrShortRead, n, read))
+	}
+	return result
+}
+
+func (s *StreamBuffer) WriteBytes(data ...byte) {
+	n, err := s.w.Write(data)
+	s.pos += n
+	if err != nil {
+		s.Fail(err)
+	}
+}
+
+// EnsureSpace is a no-op for StreamBuffer: there's no backing slice to grow
+// since bytes are written to w as soon as they're produced.
+func (s *StreamBuffer) EnsureSpace(n int) {}
+
+// Err returns the structured error recorded for this stream, or nil if
+// nothing has failed yet.
+func (s *StreamBuffer) Err() *PackError {
+	return s.err
+}
+
+func (s *StreamBuffer) Fail(cause error) {
+	if s.err != nil {
+		return
+	}
+	op := "read"
+	if s.writing {
+		op = "write"
+	}
+	s.err = &PackError{
+		Op:     op,
+		Offset: s.pos,
+		Path:   strings.Join(s.path, "."),
+		Cause:  cause,
+	}
+}
+
+func (s *StreamBuffer) PushField(name string) {
+	s.path = append(s.path, name)
+}
+
+func (s *StreamBuffer) PopField() {
+	s.path = s.path[:len(s.path)-1]
+}
+
+// PackTo packs obj directly to w using fn, without building the full
+// encoded payload in memory first.
+func PackTo[T any](w io.Writer, obj *T, fn PackFn[T]) error {
+	buf := NewStreamWriter(w)
+	fn(obj, buf)
+	if err := buf.Err(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// UnpackFrom deserializes obj directly from r using fn, without reading the
+// full encoded payload into memory first.
+func UnpackFrom[T any](r io.Reader, obj *T, fn PackFn[T]) error {
+	buf := NewStreamReader(r)
+	fn(obj, buf)
+	if err := buf.Err(); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package vpack
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPackToUnpackFrom(t *testing.T) {
+	type Other struct {
+		I1 int
+		S1 string
+	}
+	type Something struct {
+		I1 int
+		S1 string
+		O1 []Other
+	}
+
+	packOther := func(self *Other, buf PackTarget) {
+		Int(&self.I1, buf)
+		String(&self.S1, buf)
+	}
+	packSomething := func(self *Something, buf PackTarget) {
+		Int(&self.I1, buf)
+		String(&self.S1, buf)
+		Slice(&self.O1, packOther, buf)
+	}
+
+	obj1 := Something{
+		I1: 42,
+		S1: "streamed",
+		O1: []Other{{I1: 1, S1: "a"}, {I1: 2, S1: "b"}},
+	}
+
+	var pipe bytes.Buffer
+	if err := PackTo(&pipe, &obj1, packSomething); err != nil {
+		t.Fatalf("PackTo failed: %v", err)
+	}
+
+	var obj2 Something
+	if err := UnpackFrom(&pipe, &obj2, packSomething); err != nil {
+		t.Fatalf("UnpackFrom failed: %v", err)
+	}
+
+	if toJson(obj1) != toJson(obj2) {
+		t.Fatalf("objects don't match: %s != %s", toJson(obj1), toJson(obj2))
+	}
+}
+
+func TestUnpackFromShortStream(t *testing.T) {
+	type Thing struct {
+		S1 string
+	}
+	packThing := func(self *Thing, buf PackTarget) {
+		String(&self.S1, buf)
+	}
+
+	var pipe bytes.Buffer
+	pipe.Write([]byte{10}) // claims a 10-byte string but provides none
+
+	var obj Thing
+	err := UnpackFrom(&pipe, &obj, packThing)
+	if err == nil {
+		t.Fatal("expected an error from a truncated stream")
+	}
+}
@@ -22,7 +22,7 @@ func GenerateUUID() UUID {
 }
 
 // PackUUID is the serializer/deserializer function for UUID
-func PackUUID(id *UUID, buf *Buffer) {
+func PackUUID(id *UUID, buf PackTarget) {
 	for i := range id {
 		bptr := &((*id)[i])
 		Byte(bptr, buf)